@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+// TestSortEngineTiesShareARank checks that an Equal answer fuses two items
+// into the same bucket, and that the fused bucket keeps moving through later
+// merges as a single node.
+func TestSortEngineTiesShareARank(t *testing.T) {
+	cmp := func(a, b string) Ordering {
+		switch {
+		case a == "b" && b == "c":
+			return Equal
+		case a == "c" && b == "b":
+			return Equal
+		case a < b:
+			return Less
+		default:
+			return Greater
+		}
+	}
+	result := mergeSortWithCmp([]string{"b", "c", "a", "d"}, cmp)
+
+	if rankOf(result, "b") != rankOf(result, "c") {
+		t.Fatalf("expected b and c to share a rank, got ranking %v", result)
+	}
+	if rankOf(result, "a") >= rankOf(result, "b") {
+		t.Fatalf("expected a to outrank the b/c tie, got ranking %v", result)
+	}
+	if rankOf(result, "d") <= rankOf(result, "b") {
+		t.Fatalf("expected d to rank below the b/c tie, got ranking %v", result)
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// PreferenceCache remembers pairwise ordering decisions so later runs don't
+// need to re-ask a question whose answer is already known. It tracks two
+// things: a union-find of items known to be tied, and a directed graph over
+// the tie representatives where an edge winner -> loser means winner has
+// been ranked ahead of loser, directly or transitively.
+type PreferenceCache struct {
+	// parent implements union-find over tied items; find(x) is x's tie-group
+	// representative.
+	parent map[string]string
+
+	// less[winner] is the set of representatives winner is known to rank
+	// ahead of.
+	less map[string]map[string]bool
+}
+
+// NewPreferenceCache returns an empty cache.
+func NewPreferenceCache() *PreferenceCache {
+	return &PreferenceCache{parent: make(map[string]string), less: make(map[string]map[string]bool)}
+}
+
+// cacheFile is the on-disk JSON shape: tie groups, plus the less-than edges
+// between their representatives.
+type cacheFile struct {
+	Ties [][]string          `json:"ties,omitempty"`
+	Less map[string][]string `json:"less,omitempty"`
+}
+
+// LoadPreferenceCache loads a cache previously written by Save. A missing
+// file is not an error; it yields an empty cache so first runs work without
+// setup.
+func LoadPreferenceCache(path string) (*PreferenceCache, error) {
+	c := NewPreferenceCache()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+	var raw cacheFile
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	for _, group := range raw.Ties {
+		for i := 1; i < len(group); i++ {
+			c.union(group[0], group[i])
+		}
+	}
+	for winner, losers := range raw.Less {
+		set := make(map[string]bool, len(losers))
+		for _, loser := range losers {
+			set[c.find(loser)] = true
+		}
+		c.less[c.find(winner)] = set
+	}
+	return c, nil
+}
+
+// Save persists the cache as JSON: each tie group, plus each representative's
+// known losers. These are the same edges the cache holds in memory, and Infer
+// follows them transitively, so loading the file back requires no
+// recomputation.
+func (c *PreferenceCache) Save(path string) error {
+	groups := make(map[string][]string)
+	for x := range c.parent {
+		r := c.find(x)
+		groups[r] = append(groups[r], x)
+	}
+	raw := cacheFile{Less: make(map[string][]string, len(c.less))}
+	for _, group := range groups {
+		if len(group) > 1 {
+			raw.Ties = append(raw.Ties, group)
+		}
+	}
+	for winner, losers := range c.less {
+		list := make([]string, 0, len(losers))
+		for loser := range losers {
+			list = append(list, loser)
+		}
+		raw.Less[winner] = list
+	}
+	data, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// find returns x's tie-group representative, registering x if it's new.
+func (c *PreferenceCache) find(x string) string {
+	p, ok := c.parent[x]
+	if !ok {
+		c.parent[x] = x
+		return x
+	}
+	if p == x {
+		return x
+	}
+	root := c.find(p)
+	c.parent[x] = root
+	return root
+}
+
+// union merges the tie groups of a and b, moving any recorded less-than
+// edges from a's old representative onto the surviving one.
+func (c *PreferenceCache) union(a, b string) {
+	ra, rb := c.find(a), c.find(b)
+	if ra == rb {
+		return
+	}
+	c.parent[ra] = rb
+	if set, ok := c.less[ra]; ok {
+		dst := c.less[rb]
+		if dst == nil {
+			dst = make(map[string]bool)
+			c.less[rb] = dst
+		}
+		for l := range set {
+			dst[l] = true
+		}
+		delete(c.less, ra)
+	}
+	for _, losers := range c.less {
+		if losers[ra] {
+			delete(losers, ra)
+			losers[rb] = true
+		}
+	}
+}
+
+// Infer reports whether the ordering between a and b is already known,
+// either directly or transitively.
+func (c *PreferenceCache) Infer(a, b string) (ord Ordering, known bool) {
+	ra, rb := c.find(a), c.find(b)
+	if ra == rb {
+		return Equal, true
+	}
+	if c.reaches(ra, rb) {
+		return Less, true
+	}
+	if c.reaches(rb, ra) {
+		return Greater, true
+	}
+	return 0, false
+}
+
+// reaches reports whether from can reach to by following winner -> loser
+// edges between representatives.
+func (c *PreferenceCache) reaches(from, to string) bool {
+	if from == to {
+		return false
+	}
+	visited := map[string]bool{from: true}
+	stack := []string{from}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for next := range c.less[n] {
+			if next == to {
+				return true
+			}
+			if !visited[next] {
+				visited[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return false
+}
+
+// Record adds winner -> loser (in representative space) and transitively
+// closes the new edge against everything already known: every item that
+// reaches winner is connected to every item loser reaches, so a single
+// answer can settle many future questions at once.
+func (c *PreferenceCache) Record(winner, loser string) {
+	rw, rl := c.find(winner), c.find(loser)
+	if rw == rl {
+		return
+	}
+	preds := c.ancestorsOf(rw)
+	preds[rw] = true
+	succs := c.descendantsOf(rl)
+	succs[rl] = true
+
+	for p := range preds {
+		for s := range succs {
+			c.addEdge(p, s)
+		}
+	}
+}
+
+// RecordTie merges a and b into the same tie group.
+func (c *PreferenceCache) RecordTie(a, b string) {
+	c.union(a, b)
+}
+
+func (c *PreferenceCache) addEdge(winner, loser string) {
+	if winner == loser {
+		return
+	}
+	set, ok := c.less[winner]
+	if !ok {
+		set = make(map[string]bool)
+		c.less[winner] = set
+	}
+	set[loser] = true
+}
+
+// ancestorsOf returns every representative with a path leading to n
+// (excluding n itself).
+func (c *PreferenceCache) ancestorsOf(n string) map[string]bool {
+	result := make(map[string]bool)
+	for candidate := range c.less {
+		if c.reaches(candidate, n) {
+			result[candidate] = true
+		}
+	}
+	return result
+}
+
+// descendantsOf returns every representative reachable from n (excluding n
+// itself).
+func (c *PreferenceCache) descendantsOf(n string) map[string]bool {
+	result := make(map[string]bool)
+	visited := map[string]bool{n: true}
+	stack := []string{n}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for next := range c.less[cur] {
+			if !visited[next] {
+				visited[next] = true
+				result[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return result
+}
+
+// oracle wraps a base comparator so that every pairwise decision first checks
+// the cache and only falls back to asking when the answer isn't already
+// inferable, recording the answer (transitively closed) for next time.
+func (c *PreferenceCache) oracle(base Comparator) Comparator {
+	return func(a, b string) Ordering {
+		if ord, known := c.Infer(a, b); known {
+			return ord
+		}
+		ord := base(a, b)
+		switch ord {
+		case Less:
+			c.Record(a, b)
+		case Greater:
+			c.Record(b, a)
+		case Equal:
+			c.RecordTie(a, b)
+		}
+		return ord
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+// TestPreferenceCacheTransitiveInference checks that recording a < b and
+// b < c lets the cache answer a vs c without being told directly.
+func TestPreferenceCacheTransitiveInference(t *testing.T) {
+	c := NewPreferenceCache()
+	c.Record("a", "b")
+	c.Record("b", "c")
+
+	ord, known := c.Infer("a", "c")
+	if !known {
+		t.Fatal("expected a vs c to be inferable transitively")
+	}
+	if ord != Less {
+		t.Fatalf("expected a to rank ahead of c, got %v", ord)
+	}
+}
+
+// TestPreferenceCacheTieMergesGroupsAndEdges checks that tying two items
+// merges their tie groups and carries any previously recorded edges onto the
+// surviving representative.
+func TestPreferenceCacheTieMergesGroupsAndEdges(t *testing.T) {
+	c := NewPreferenceCache()
+	c.Record("a", "b")
+	c.RecordTie("b", "bb")
+
+	if ord, known := c.Infer("a", "bb"); !known || ord != Less {
+		t.Fatalf("expected a to rank ahead of bb after tying b and bb, got ord=%v known=%v", ord, known)
+	}
+	if ord, known := c.Infer("b", "bb"); !known || ord != Equal {
+		t.Fatalf("expected b and bb to be known tied, got ord=%v known=%v", ord, known)
+	}
+}
+
+// TestPreferenceCacheSaveLoadRoundTrip checks that a cache saved to disk and
+// reloaded still answers the same transitively-closed questions without
+// asking again.
+func TestPreferenceCacheSaveLoadRoundTrip(t *testing.T) {
+	c := NewPreferenceCache()
+	c.Record("a", "b")
+	c.Record("b", "c")
+	c.RecordTie("x", "y")
+
+	path := t.TempDir() + "/cache.json"
+	if err := c.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadPreferenceCache(path)
+	if err != nil {
+		t.Fatalf("LoadPreferenceCache failed: %v", err)
+	}
+	if ord, known := loaded.Infer("a", "c"); !known || ord != Less {
+		t.Fatalf("expected reloaded cache to infer a ahead of c, got ord=%v known=%v", ord, known)
+	}
+	if ord, known := loaded.Infer("x", "y"); !known || ord != Equal {
+		t.Fatalf("expected reloaded cache to know x and y are tied, got ord=%v known=%v", ord, known)
+	}
+}
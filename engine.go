@@ -0,0 +1,195 @@
+package main
+
+// Ordering is the three-valued result of comparing two items: whether a
+// should rank before b, after b, or tie with b.
+type Ordering int
+
+const (
+	Less Ordering = iota
+	Greater
+	Equal
+)
+
+// Comparator decides how a and b should be ranked relative to each other.
+type Comparator func(a, b string) Ordering
+
+// bucket is a group of items tied with each other; a fully-resolved ranking
+// is a sequence of buckets from best to worst.
+type bucket = []string
+
+// Ranking is the final (or best-effort) output of a SortEngine: buckets from
+// best to worst, with more than one item in a bucket when they tied.
+type Ranking = [][]string
+
+// SortEngine drives a bottom-up (iterative) merge sort whose only state is an
+// ordered log of past answers plus the original items. Because the algorithm
+// is fully determined by (items, log), undo/redo/stop-early all reduce to
+// trimming or replaying that log instead of unwinding a recursive call stack
+// or hand-rolling a resumable merge stack — simpler to get right, at the cost
+// of re-simulating the merge from scratch on every step (cheap at the sizes a
+// human can interactively rank). Equal answers merge the two items into a
+// single tied bucket, which is then carried through later merges as one node.
+type SortEngine struct {
+	items []string
+	log   []Ordering
+	redo  []Ordering
+}
+
+// NewSortEngine starts a new ranking session over items.
+func NewSortEngine(items []string) *SortEngine {
+	cp := make([]string, len(items))
+	copy(cp, items)
+	return &SortEngine{items: cp}
+}
+
+// simResult is the outcome of replaying the log against the items.
+type simResult struct {
+	needsAnswer bool
+	a, b        string // pending comparison, valid when needsAnswer
+	partial     Ranking
+	done        bool
+	result      Ranking
+}
+
+// Next returns the next pair the engine needs an ordering for. ok is false
+// once sorting is complete (call Result instead).
+func (e *SortEngine) Next() (a, b string, ok bool) {
+	r := e.simulate()
+	return r.a, r.b, r.needsAnswer
+}
+
+// Done reports whether every comparison needed to produce a final ranking
+// has been answered.
+func (e *SortEngine) Done() bool {
+	return e.simulate().done
+}
+
+// Answer records the ordering for the pair most recently returned by Next and
+// clears any pending redo history, matching ordinary editor undo/redo
+// semantics: answering a new comparison after an undo abandons the undone
+// branch.
+func (e *SortEngine) Answer(ord Ordering) {
+	e.log = append(e.log, ord)
+	e.redo = nil
+}
+
+// Undo reverts the most recent answer, if any, making it askable again.
+func (e *SortEngine) Undo() bool {
+	if len(e.log) == 0 {
+		return false
+	}
+	n := len(e.log) - 1
+	e.redo = append(e.redo, e.log[n])
+	e.log = e.log[:n]
+	return true
+}
+
+// Redo re-applies the most recently undone answer, if any.
+func (e *SortEngine) Redo() bool {
+	if len(e.redo) == 0 {
+		return false
+	}
+	n := len(e.redo) - 1
+	e.log = append(e.log, e.redo[n])
+	e.redo = e.redo[:n]
+	return true
+}
+
+// Result returns the final ranking. It panics if sorting isn't done; callers
+// should check Done (or prefer PartialResult to stop early).
+func (e *SortEngine) Result() Ranking {
+	r := e.simulate()
+	if !r.done {
+		panic("SortEngine: Result called before sorting finished")
+	}
+	return r.result
+}
+
+// PartialResult returns a best-effort ranking usable even if the session is
+// stopped before every comparison has been answered: runs that are fully
+// merged keep their resolved order, and anything not yet merged is appended
+// in its original relative order.
+func (e *SortEngine) PartialResult() Ranking {
+	r := e.simulate()
+	if r.done {
+		return r.result
+	}
+	return r.partial
+}
+
+// simulate replays the answer log through a bottom-up merge sort of e.items,
+// stopping at the first comparison without a logged answer.
+func (e *SortEngine) simulate() simResult {
+	runs := make([][]bucket, len(e.items))
+	for i, it := range e.items {
+		runs[i] = []bucket{{it}}
+	}
+	idx := 0
+	for len(runs) > 1 {
+		next := make([][]bucket, 0, (len(runs)+1)/2)
+		for i := 0; i+1 < len(runs); i += 2 {
+			merged, a, b, needsAnswer, consumed := replayMerge(runs[i], runs[i+1], e.log[idx:])
+			idx += consumed
+			if needsAnswer {
+				partial := append([][]bucket{}, next...)
+				partial = append(partial, merged)
+				partial = append(partial, runs[i+2:]...)
+				return simResult{needsAnswer: true, a: a, b: b, partial: flatten(partial)}
+			}
+			next = append(next, merged)
+		}
+		if len(runs)%2 == 1 {
+			next = append(next, runs[len(runs)-1])
+		}
+		runs = next
+	}
+	var result Ranking
+	if len(runs) == 1 {
+		result = runs[0]
+	}
+	return simResult{done: true, result: result}
+}
+
+// replayMerge merges left and right runs of buckets, consuming one entry of
+// answers per comparison between bucket representatives. Equal answers fuse
+// the two buckets into one, which then moves through the rest of the merge
+// (and any later merges) as a single node. If answers runs out before the
+// merge does, it reports the next pair needing an answer along with the
+// in-progress merge of everything decided so far, followed by the undecided
+// rest of left and then of right.
+func replayMerge(left, right []bucket, answers []Ordering) (merged []bucket, a, b string, needsAnswer bool, consumed int) {
+	i, j, k := 0, 0, 0
+	out := make([]bucket, 0, len(left)+len(right))
+	for i < len(left) && j < len(right) {
+		if k >= len(answers) {
+			out = append(out, left[i:]...)
+			out = append(out, right[j:]...)
+			return out, left[i][0], right[j][0], true, k
+		}
+		switch answers[k] {
+		case Less:
+			out = append(out, left[i])
+			i++
+		case Greater:
+			out = append(out, right[j])
+			j++
+		case Equal:
+			tied := append(append(bucket{}, left[i]...), right[j]...)
+			out = append(out, tied)
+			i++
+			j++
+		}
+		k++
+	}
+	out = append(out, left[i:]...)
+	out = append(out, right[j:]...)
+	return out, "", "", false, k
+}
+
+func flatten(runs [][]bucket) Ranking {
+	out := Ranking{}
+	for _, r := range runs {
+		out = append(out, r...)
+	}
+	return out
+}
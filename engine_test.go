@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+// rankOf returns the 1-based rank of item in ranking (ties share a rank,
+// matching printRanking's numbering), or -1 if item isn't present.
+func rankOf(ranking Ranking, item string) int {
+	rank := 1
+	for _, group := range ranking {
+		for _, it := range group {
+			if it == item {
+				return rank
+			}
+		}
+		rank += len(group)
+	}
+	return -1
+}
+
+// TestSortEngineUndoRedo checks that Undo reverts the most recent answer
+// (making its pair askable again) and Redo re-applies it, and that answering
+// a fresh comparison after an undo discards the redo history.
+func TestSortEngineUndoRedo(t *testing.T) {
+	e := NewSortEngine([]string{"x", "y"})
+
+	a, b, ok := e.Next()
+	if !ok {
+		t.Fatal("expected a pending comparison for two items")
+	}
+	e.Answer(Less)
+	if !e.Done() {
+		t.Fatal("expected sorting to be done after one answer over two items")
+	}
+
+	if !e.Undo() {
+		t.Fatal("Undo should report success when there's a logged answer")
+	}
+	if e.Done() {
+		t.Fatal("expected sorting to be pending again after Undo")
+	}
+	a2, b2, ok := e.Next()
+	if !ok || a2 != a || b2 != b {
+		t.Fatalf("expected the undone pair (%s, %s) to be askable again, got (%s, %s, %v)", a, b, a2, b2, ok)
+	}
+
+	if !e.Redo() {
+		t.Fatal("Redo should report success right after an Undo")
+	}
+	if !e.Done() {
+		t.Fatal("expected sorting to be done again after Redo")
+	}
+
+	e.Undo()
+	e.Answer(Greater)
+	if e.Redo() {
+		t.Fatal("Redo should fail after a fresh answer discards the redo history")
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import "sort"
+
+// fordJohnsonSort ranks items using the Ford-Johnson "merge insertion"
+// algorithm, which is near information-theoretically optimal in the number
+// of comparisons it asks for.
+//
+// It pairs items up and compares within each pair: the worse-ranked item of
+// each pair (b_i) recurses into the same algorithm to build a sorted "main
+// chain", while the better-ranked item (a_i) is known to precede its own
+// b_i and is inserted into the chain afterwards — a_1 directly before b_1
+// for free, the rest via a binary search bounded above by their paired b_i's
+// position, visited in Jacobsthal-numbered order so every insertion's search
+// range is one less than a power of two (the fewest comparisons a binary
+// search can need for that many candidate positions).
+//
+// Ties are treated the same as a strict "a before b": cmp is only ever asked
+// whether one of two specific items should come first, so the comparator
+// interface is unchanged, but the resulting order breaks ties arbitrarily
+// rather than grouping them.
+func fordJohnsonSort(items []string, cmp Comparator) []string {
+	n := len(items)
+	if n <= 1 {
+		out := make([]string, n)
+		copy(out, items)
+		return out
+	}
+
+	bItems := make([]string, 0, n/2)
+	pairA := make(map[string]string, n/2) // bItems[i] -> its paired, better-ranked a
+	for i := 0; i+1 < n; i += 2 {
+		a, b := rankPair(cmp, items[i], items[i+1])
+		bItems = append(bItems, b)
+		pairA[b] = a
+	}
+
+	var leftover string
+	hasLeftover := n%2 == 1
+	if hasLeftover {
+		leftover = items[n-1]
+	}
+
+	mainChain := fordJohnsonSort(bItems, cmp)
+	t := len(mainChain)
+
+	// a_1 is known to precede b_1, and b_1 is already the front of the
+	// chain, so a_1 must be the very first element - no comparison needed.
+	chain := make([]string, 0, n)
+	if t > 0 {
+		chain = append(chain, pairA[mainChain[0]])
+	}
+	chain = append(chain, mainChain...)
+
+	total := t
+	if hasLeftover {
+		total = t + 1
+	}
+
+	// Insert the remaining a_i (and the odd leftover, if any, treated as
+	// a_{t+1} with no upper bound) in Jacobsthal order: a_3,a_2, a_5,a_4,
+	// a_11..a_6, ...
+	prevJac := 1
+	for k := 2; prevJac < total; k++ {
+		next := jacobsthal(k)
+		if next > total {
+			next = total
+		}
+		for idx := next; idx > prevJac; idx-- {
+			var item string
+			var bound int
+			if idx <= t {
+				b := mainChain[idx-1]
+				item = pairA[b]
+				bound = indexOf(chain, b)
+			} else {
+				item = leftover
+				bound = len(chain)
+			}
+			chain = insertSorted(chain, item, bound, cmp)
+		}
+		prevJac = next
+	}
+
+	return chain
+}
+
+// rankPair compares a and b and returns (better, worse) where better is the
+// one that should be ranked first.
+func rankPair(cmp Comparator, a, b string) (better, worse string) {
+	if cmp(a, b) != Greater {
+		return a, b
+	}
+	return b, a
+}
+
+// indexOf returns the position of item in chain.
+func indexOf(chain []string, item string) int {
+	for i, it := range chain {
+		if it == item {
+			return i
+		}
+	}
+	return len(chain)
+}
+
+// insertSorted binary-searches chain[:bound] for item's place and inserts it
+// there, keeping chain sorted best-to-worst.
+func insertSorted(chain []string, item string, bound int, cmp Comparator) []string {
+	pos := sort.Search(bound, func(i int) bool {
+		return cmp(chain[i], item) == Greater
+	})
+	chain = append(chain, "")
+	copy(chain[pos+1:], chain[pos:])
+	chain[pos] = item
+	return chain
+}
+
+// jacobsthal returns the k-th term (k>=1) of the sequence 1, 3, 5, 11, 21,
+// 43, ... used to order Ford-Johnson insertions.
+func jacobsthal(k int) int {
+	sign := 1
+	if k%2 == 1 {
+		sign = -1
+	}
+	return (pow2(k+1) + sign) / 3
+}
+
+func pow2(k int) int {
+	return 1 << uint(k)
+}
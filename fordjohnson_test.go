@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// optimalComparisons[n-1] is the minimum number of comparisons needed to sort
+// n elements (the "sorting numbers", OEIS A001768), which merge insertion is
+// known to achieve.
+var optimalComparisons = []int{
+	0, 1, 3, 5, 7, 10, 13, 16, 19, 22,
+	26, 30, 34, 38, 42, 46, 50, 54, 58, 62,
+}
+
+func TestFordJohnsonSortNeverExceedsOptimalComparisons(t *testing.T) {
+	for n := 1; n <= 20; n++ {
+		items := make([]string, n)
+		for i := range items {
+			items[i] = fmt.Sprintf("item%d", i)
+		}
+
+		calls := 0
+		cmp := func(a, b string) Ordering {
+			calls++
+			ai, bi := itemIndex(a), itemIndex(b)
+			switch {
+			case ai < bi:
+				return Less
+			case ai > bi:
+				return Greater
+			default:
+				return Equal
+			}
+		}
+
+		fordJohnsonSort(items, cmp)
+
+		want := optimalComparisons[n-1]
+		if calls > want {
+			t.Errorf("n=%d: fordJohnsonSort used %d comparisons, want at most %d", n, calls, want)
+		}
+	}
+}
+
+func itemIndex(name string) int {
+	i, err := strconv.Atoi(strings.TrimPrefix(name, "item"))
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/sushi30/go-interactive-tournament/server"
 )
 
 // Interactive tournament-style (merge) sort.
@@ -21,8 +23,22 @@ func main() {
 	filePath := flag.String("file", "", "Path to a file with one item per line")
 	tuiMode := flag.Bool("tui", false, "Launch interactive TUI (Bubbletea)")
 	outPath := flag.String("o", "", "Write final ranking to a file")
+	cachePath := flag.String("cache", "", "Path to a JSON file remembering past preferences, to skip questions whose answer is already known")
+	algo := flag.String("algo", "ford-johnson", "Sorting algorithm to drive the questions: merge (supports ties, undo/redo in the TUI) or ford-johnson (fewest possible questions)")
+	streamFlag := flag.Bool("stream", false, "After the initial ranking, keep reading newline-delimited items from stdin and insert each one without re-asking settled comparisons")
+	watchPath := flag.String("watch", "", "After the initial ranking, poll this file for appended lines and insert each new item as it appears")
+	serveAddr := flag.String("serve", "", "Run an SSH server at this address (e.g. :2222) instead of sorting locally, letting multiple clients vote on a shared item list loaded from -file")
+	roomName := flag.String("room", "tournament", "Room name shown to clients connecting to -serve")
+	voteMethod := flag.String("vote", "copeland", "How -serve aggregates votes into a final ranking: copeland (also majority) or borda")
 	flag.Parse()
 
+	switch *algo {
+	case "merge", "ford-johnson":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown -algo %q; expected merge or ford-johnson\n", *algo)
+		os.Exit(1)
+	}
+
 	items := []string{}
 
 	// Order of preference for input:
@@ -70,88 +86,102 @@ func main() {
 		os.Exit(1)
 	}
 
-	if *tuiMode {
-		sorted := RunTUI(items)
-		// Print final ranking to stdout (RunTUI no longer prints)
-		fmt.Println("\nFinal ranking (best -> worst):")
-		for i, it := range sorted {
-			fmt.Printf("%d. %s\n", i+1, it)
-		}
-		if *outPath != "" {
-			if err := writeResultToFile(sorted, *outPath); err != nil {
-				fmt.Fprintf(os.Stderr, "failed to write output: %v\n", err)
-				os.Exit(1)
-			}
+	if *serveAddr != "" {
+		fmt.Printf("Serving room %q on %s (%d items)...\n", *roomName, *serveAddr, len(items))
+		if err := server.Serve(*serveAddr, *roomName, items, *voteMethod); err != nil {
+			fmt.Fprintf(os.Stderr, "server error: %v\n", err)
+			os.Exit(1)
 		}
 		return
 	}
 
-	fmt.Printf("\nGot %d items. We'll ask pairwise questions to rank them.\n", len(items))
-	fmt.Println("On each prompt enter 1 or 2 to choose the item you prefer. Enter q to quit.\n")
+	var cache *PreferenceCache
+	if *cachePath != "" {
+		var err error
+		cache, err = LoadPreferenceCache(*cachePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load cache: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	sorted := interactiveMergeSort(items)
+	cmp := Comparator(askPreference)
+	if cache != nil {
+		cmp = cache.oracle(cmp)
+	}
+
+	var ranking Ranking
+	if *tuiMode {
+		ranking = RunTUI(items, cache, *algo, *streamFlag, *watchPath)
+	} else {
+		fmt.Printf("\nGot %d items. We'll ask pairwise questions to rank them.\n", len(items))
+		fmt.Println("On each prompt enter 1 or 2 to choose the item you prefer, 3 (or =) if they're tied. Enter q to quit.")
+		ranking = interactiveMergeSort(items, cmp, *algo)
+	}
 
 	fmt.Println("\nFinal ranking (best -> worst):")
-	for i, it := range sorted {
-		fmt.Printf("%d. %s\n", i+1, it)
+	printRanking(ranking)
+
+	switch {
+	case !*tuiMode:
+		ranking = streamAdditions(ranking, cmp, *streamFlag, *watchPath)
+	case *algo == "ford-johnson":
+		// Ford-Johnson's TUI doesn't wire in streaming either (see RunTUI),
+		// so apply it the same way as the non-TUI path, after the session
+		// ends.
+		ranking = streamAdditions(ranking, cmp, *streamFlag, *watchPath)
+	case *streamFlag:
+		// -watch was already serviced live inside the TUI session (see
+		// model.enterStreaming); -stream (stdin) can't share stdin with
+		// Bubbletea's keystroke reading, so it still runs here, same as a
+		// non-TUI run — inside the TUI, 'a' added items by hand instead.
+		fmt.Println("\n-stream keeps reading stdin now that the TUI session has ended.")
+		ranking = streamAdditions(ranking, cmp, *streamFlag, "")
 	}
+
 	if *outPath != "" {
-		if err := writeResultToFile(sorted, *outPath); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to write output file: %v\n", err)
+		if err := writeResultToFile(ranking, *outPath); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write output: %v\n", err)
 			os.Exit(1)
 		}
 	}
+	if cache != nil {
+		if err := cache.Save(*cachePath); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to save cache: %v\n", err)
+		}
+	}
 }
 
-// interactiveMergeSort performs a standard merge sort but asks the user
-// to compare elements during merge instead of using a deterministic comparison.
-func interactiveMergeSort(items []string) []string {
-	if len(items) <= 1 {
-		// Make a copy to avoid aliasing
-		out := make([]string, len(items))
-		copy(out, items)
-		return out
-	}
-	mid := len(items) / 2
-	left := interactiveMergeSort(items[:mid])
-	right := interactiveMergeSort(items[mid:])
-	return interactiveMerge(left, right)
+// interactiveMergeSort ranks items by asking the user to compare them,
+// routing every pairwise decision through the chosen algorithm: "merge"
+// (groups ties, supports the TUI's undo/redo) or "ford-johnson" (asks the
+// fewest possible questions).
+func interactiveMergeSort(items []string, cmp Comparator, algo string) Ranking {
+	if algo == "ford-johnson" {
+		return singletons(fordJohnsonSort(items, cmp))
+	}
+	return mergeSortWithCmp(items, cmp)
 }
 
-// interactiveMerge merges two sorted slices by asking the user which item they prefer.
-func interactiveMerge(left, right []string) []string {
-	i, j := 0, 0
-	out := make([]string, 0, len(left)+len(right))
-	for i < len(left) && j < len(right) {
-		// Ask user which item they prefer: left[i] or right[j]
-		if askPreference(left[i], right[j]) {
-			out = append(out, left[i])
-			i++
-		} else {
-			out = append(out, right[j])
-			j++
-		}
-	}
-	// append remaining
-	for i < len(left) {
-		out = append(out, left[i])
-		i++
-	}
-	for j < len(right) {
-		out = append(out, right[j])
-		j++
+// singletons wraps a flat ranking (no ties) into a Ranking of one-item
+// buckets.
+func singletons(sorted []string) Ranking {
+	out := make(Ranking, len(sorted))
+	for i, it := range sorted {
+		out[i] = []string{it}
 	}
 	return out
 }
 
-// askPreference returns true if the user prefers a over b.
-// It prompts until it receives a valid answer: 1 => a, 2 => b, q => quit.
-func askPreference(a, b string) bool {
+// askPreference asks the user how a and b should be ranked relative to each
+// other. It prompts until it receives a valid answer: 1 => a before b,
+// 2 => b before a, 3 (or =) => tied, q => quit.
+func askPreference(a, b string) Ordering {
 	for {
 		fmt.Println("Which do you prefer?")
 		fmt.Printf("  1) %s\n", a)
 		fmt.Printf("  2) %s\n", b)
-		fmt.Print("Enter 1 or 2 (or q to quit): ")
+		fmt.Print("Enter 1, 2, 3 for a tie (or q to quit): ")
 
 		resp, err := reader.ReadString('\n')
 		if err != nil {
@@ -161,29 +191,47 @@ func askPreference(a, b string) bool {
 		resp = strings.TrimSpace(resp)
 		switch strings.ToLower(resp) {
 		case "1", "a":
-			return true
+			return Less
 		case "2", "b":
-			return false
+			return Greater
+		case "3", "=":
+			return Equal
 		case "q", "quit", "exit":
 			fmt.Println("Quitting.")
 			os.Exit(0)
 		default:
-			fmt.Println("Invalid input; please enter 1 or 2 (or q to quit).")
+			fmt.Println("Invalid input; please enter 1, 2 or 3 (or q to quit).")
+		}
+	}
+}
+
+// printRanking prints a ranking to stdout, one line per item, with tied
+// items sharing the same rank number (e.g. "3. foo" / "3. bar" / "5. baz").
+func printRanking(ranking Ranking) {
+	rank := 1
+	for _, group := range ranking {
+		for _, it := range group {
+			fmt.Printf("%d. %s\n", rank, it)
 		}
+		rank += len(group)
 	}
 }
 
-func writeResultToFile(sorted []string, path string) error {
+func writeResultToFile(ranking Ranking, path string) error {
 	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 	w := bufio.NewWriter(f)
-	for i, it := range sorted {
-		if _, err := fmt.Fprintf(w, "%d. %s\n", i+1, it); err != nil {
-			return err
+	rank := 1
+	for _, group := range ranking {
+		for _, it := range group {
+			if _, err := fmt.Fprintf(w, "%d. %s\n", rank, it); err != nil {
+				return err
+			}
 		}
+		rank += len(group)
 	}
 	return w.Flush()
 }
@@ -0,0 +1,91 @@
+package server
+
+import "sort"
+
+// Tally tracks, for every pair of items that has been voted on, how many
+// votes each side received.
+type Tally struct {
+	// wins[a][b] is the number of votes cast for a over b.
+	wins map[string]map[string]int
+}
+
+// NewTally returns an empty vote tally.
+func NewTally() *Tally {
+	return &Tally{wins: make(map[string]map[string]int)}
+}
+
+// Record adds one vote for winner over loser.
+func (t *Tally) Record(winner, loser string) {
+	set, ok := t.wins[winner]
+	if !ok {
+		set = make(map[string]int)
+		t.wins[winner] = set
+	}
+	set[loser]++
+}
+
+// Ranking aggregates the tally into a best-to-worst ranking using the given
+// method:
+//   - "copeland" (also "majority"): score an item by wins minus losses
+//     against every opponent it faced, where the majority of votes on a pair
+//     decides that one win or loss (ties in the vote count score neither).
+//   - "borda": score an item by the total number of votes it won across
+//     every pairing, so a lopsided win counts for more than a narrow one.
+//
+// Items tie (and share a rank) when their scores are equal.
+func (t *Tally) Ranking(items []string, method string) Ranking {
+	scores := make(map[string]int, len(items))
+	switch method {
+	case "borda":
+		for _, item := range items {
+			scores[item] = t.bordaScore(item)
+		}
+	default: // "copeland", "majority"
+		for _, item := range items {
+			scores[item] = t.copelandScore(item, items)
+		}
+	}
+
+	sorted := append([]string(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return scores[sorted[i]] > scores[sorted[j]]
+	})
+
+	ranking := Ranking{}
+	for _, item := range sorted {
+		if len(ranking) > 0 && scores[ranking[len(ranking)-1][0]] == scores[item] {
+			ranking[len(ranking)-1] = append(ranking[len(ranking)-1], item)
+			continue
+		}
+		ranking = append(ranking, []string{item})
+	}
+	return ranking
+}
+
+// copelandScore counts item's wins minus its losses against every other
+// item, where the pairing's majority vote decides a single win or loss.
+func (t *Tally) copelandScore(item string, items []string) int {
+	score := 0
+	for _, opp := range items {
+		if opp == item {
+			continue
+		}
+		w, l := t.wins[item][opp], t.wins[opp][item]
+		switch {
+		case w > l:
+			score++
+		case l > w:
+			score--
+		}
+	}
+	return score
+}
+
+// bordaScore sums every vote item won across all of its pairings.
+func (t *Tally) bordaScore(item string) int {
+	score := 0
+	for _, n := range t.wins[item] {
+		score += n
+	}
+	return score
+}
@@ -0,0 +1,178 @@
+// Package server implements a shared multi-user tournament: a group of SSH
+// clients connected to the same room are all asked the same round-robin set
+// of pairwise questions, and their votes are aggregated into one ranking.
+package server
+
+import "sync"
+
+// Ranking is a best-to-worst list of buckets, with more than one item in a
+// bucket when their aggregated scores tied.
+type Ranking = [][]string
+
+// Pair is one of the questions a room asks: which of A or B do you prefer?
+type Pair struct {
+	A, B string
+}
+
+// Room is a tournament shared by every participant connected to it: a fixed
+// item list, a round-robin list of pairs to vote on, and the votes cast so
+// far. The zero value is not usable; build one with NewRoom.
+type Room struct {
+	mu sync.Mutex
+
+	Name  string
+	Items []string
+
+	pairs   []Pair
+	current int // index into pairs of the question being voted on
+
+	tally *Tally
+
+	participants map[string]bool
+	votedCurrent map[string]bool
+
+	host string // participant ID allowed to EndVoting early; "" if none connected
+
+	ended bool
+}
+
+// NewRoom builds a room over items, with every distinct pair queued up for a
+// round-robin vote.
+func NewRoom(name string, items []string) *Room {
+	r := &Room{
+		Name:         name,
+		Items:        append([]string(nil), items...),
+		tally:        NewTally(),
+		participants: make(map[string]bool),
+		votedCurrent: make(map[string]bool),
+	}
+	for i := 0; i < len(items); i++ {
+		for j := i + 1; j < len(items); j++ {
+			r.pairs = append(r.pairs, Pair{A: items[i], B: items[j]})
+		}
+	}
+	if len(r.pairs) == 0 {
+		r.ended = true
+	}
+	return r
+}
+
+// Join registers a participant (keyed however the caller likes, e.g. a
+// session's remote address) and returns the live participant count. The
+// first participant to join becomes the host (see IsHost); if the room is
+// otherwise empty, a later joiner picks up the role instead.
+func (r *Room) Join(participantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.participants[participantID] = true
+	if r.host == "" {
+		r.host = participantID
+	}
+	return len(r.participants)
+}
+
+// Leave removes a participant and returns the live participant count. If the
+// host leaves, the role passes to an arbitrary remaining participant so the
+// room always has someone who can EndVoting early, as long as anyone is
+// still connected.
+func (r *Room) Leave(participantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.participants, participantID)
+	delete(r.votedCurrent, participantID)
+	if participantID == r.host {
+		r.host = ""
+		for id := range r.participants {
+			r.host = id
+			break
+		}
+	}
+	r.advanceIfReady()
+	return len(r.participants)
+}
+
+// ParticipantCount reports how many clients are currently connected.
+func (r *Room) ParticipantCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.participants)
+}
+
+// IsHost reports whether participantID currently holds the host role.
+func (r *Room) IsHost(participantID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return participantID != "" && participantID == r.host
+}
+
+// CurrentPair returns the question currently being voted on. ok is false
+// once voting has ended (either every pair was decided or the host ended it
+// early), at which point Ranking reflects the final result.
+func (r *Room) CurrentPair() (pair Pair, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ended || r.current >= len(r.pairs) {
+		return Pair{}, false
+	}
+	return r.pairs[r.current], true
+}
+
+// Vote records participantID's preference on the current pair (preferA true
+// means they chose A). Once every connected participant has voted on it, the
+// room advances to the next pair.
+func (r *Room) Vote(participantID string, preferA bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ended || r.current >= len(r.pairs) {
+		return
+	}
+	pair := r.pairs[r.current]
+	if preferA {
+		r.tally.Record(pair.A, pair.B)
+	} else {
+		r.tally.Record(pair.B, pair.A)
+	}
+	r.votedCurrent[participantID] = true
+	r.advanceIfReady()
+}
+
+// advanceIfReady moves to the next pair once every connected participant has
+// voted on the current one. Callers must hold r.mu.
+func (r *Room) advanceIfReady() {
+	if r.ended || len(r.participants) == 0 {
+		return
+	}
+	for id := range r.participants {
+		if !r.votedCurrent[id] {
+			return
+		}
+	}
+	r.current++
+	r.votedCurrent = make(map[string]bool)
+	if r.current >= len(r.pairs) {
+		r.ended = true
+	}
+}
+
+// EndVoting stops the room early (intended to be host-only at the call
+// site), finalizing whatever ranking the votes so far produce.
+func (r *Room) EndVoting() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ended = true
+}
+
+// Ended reports whether voting is over.
+func (r *Room) Ended() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ended
+}
+
+// Ranking aggregates every vote cast so far using method ("copeland",
+// "borda", or "majority", an alias for "copeland") into a final ranking.
+func (r *Room) Ranking(method string) Ranking {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tally.Ranking(r.Items, method)
+}
@@ -0,0 +1,26 @@
+package server
+
+import "testing"
+
+// TestRoomReassignsHostOnLeave checks that when the host disconnects, the
+// role passes to another connected participant instead of being stranded
+// with nobody able to end voting early.
+func TestRoomReassignsHostOnLeave(t *testing.T) {
+	r := NewRoom("test", []string{"a", "b", "c"})
+
+	r.Join("host")
+	r.Join("guest")
+
+	if !r.IsHost("host") {
+		t.Fatal("expected the first joiner to be host")
+	}
+	if r.IsHost("guest") {
+		t.Fatal("expected the second joiner not to be host")
+	}
+
+	r.Leave("host")
+
+	if !r.IsHost("guest") {
+		t.Fatal("expected the host role to pass to the remaining participant")
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+	gossh "github.com/charmbracelet/ssh"
+)
+
+// tickInterval controls how often a connected client's view is refreshed to
+// pick up changes driven by other participants (new current pair, updated
+// participant count, voting ended).
+const tickInterval = 250 * time.Millisecond
+
+// Serve starts an SSH server at addr hosting a single room built from items.
+// Every client that connects sees the same pairwise questions and a live
+// participant count; the room's host (initially whoever connects first, and
+// reassigned to another connected participant if they leave, see
+// Room.Leave) can press 'e' to end voting early and broadcast the aggregated
+// ranking (method: "copeland", "borda", or "majority") to everyone still
+// connected.
+func Serve(addr, roomName string, items []string, method string) error {
+	room := NewRoom(roomName, items)
+
+	s, err := wish.NewServer(
+		wish.WithAddress(addr),
+		wish.WithMiddleware(
+			bubbletea.Middleware(func(s gossh.Session) (tea.Model, []tea.ProgramOption) {
+				id := s.RemoteAddr().String()
+				room.Join(id)
+				go func() {
+					<-s.Context().Done()
+					room.Leave(id)
+				}()
+				return newClientModel(room, id, method), []tea.ProgramOption{tea.WithAltScreen()}
+			}),
+			logging.Middleware(),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("configure SSH server: %w", err)
+	}
+	return s.ListenAndServe()
+}
+
+// ----------------------
+// per-client Bubbletea model
+// ----------------------
+
+type tickMsg struct{}
+
+type clientModel struct {
+	room     *Room
+	self     string
+	method   string
+	a, b     string
+	awaiting bool
+	isHost   bool
+	result   Ranking
+}
+
+func newClientModel(room *Room, self string, method string) clientModel {
+	m := clientModel{room: room, self: self, method: method}
+	m.refresh()
+	return m
+}
+
+// refresh pulls the room's current state, including who holds the host
+// role right now — it can move between participants (see Room.Leave), so
+// this is re-read on every tick rather than fixed at connect time.
+func (m *clientModel) refresh() {
+	m.isHost = m.room.IsHost(m.self)
+	if m.room.Ended() {
+		m.result = m.room.Ranking(m.method)
+		m.awaiting = false
+		return
+	}
+	pair, ok := m.room.CurrentPair()
+	if !ok {
+		m.result = m.room.Ranking(m.method)
+		m.awaiting = false
+		return
+	}
+	m.a, m.b = pair.A, pair.B
+	m.awaiting = true
+}
+
+func pollTick() tea.Cmd {
+	return tea.Tick(tickInterval, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+func (m clientModel) Init() tea.Cmd {
+	return pollTick()
+}
+
+func (m clientModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tickMsg:
+		m.refresh()
+		return m, pollTick()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "left", "h":
+			if m.awaiting {
+				m.room.Vote(m.self, true)
+				m.refresh()
+			}
+			return m, nil
+		case "right", "l":
+			if m.awaiting {
+				m.room.Vote(m.self, false)
+				m.refresh()
+			}
+			return m, nil
+		case "e":
+			if m.isHost {
+				m.room.EndVoting()
+				m.refresh()
+			}
+			return m, nil
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m clientModel) View() string {
+	header := fmt.Sprintf("Room %q — %d participant(s) connected\n\n", m.room.Name, m.room.ParticipantCount())
+	if m.isHost {
+		header += "You are the host: press 'e' to end voting early.\n\n"
+	}
+
+	if !m.awaiting {
+		out := header + "Voting has ended. Final ranking (best -> worst):\n\n"
+		rank := 1
+		for _, group := range m.result {
+			for _, it := range group {
+				out += fmt.Sprintf("%d. %s\n", rank, it)
+			}
+			rank += len(group)
+		}
+		return out
+	}
+
+	return header + fmt.Sprintf("  ← %-30s    %-30s →\n\n  Use ← / → (or h / l) to vote. q to quit.\n\n", m.a, m.b)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Inserter incrementally inserts new items into an already-computed ranking
+// using binary search against the existing order, so only O(log n) new
+// comparisons are needed per insertion and every answer already given stays
+// valid — the opposite of restarting the sort from scratch.
+type Inserter struct {
+	cmp     Comparator
+	ranking Ranking
+}
+
+// NewInserter starts an Inserter from a ranking already produced by one of
+// the batch sorts.
+func NewInserter(initial Ranking, cmp Comparator) *Inserter {
+	r := make(Ranking, len(initial))
+	copy(r, initial)
+	return &Inserter{cmp: cmp, ranking: r}
+}
+
+// Insert binary-searches the current ranking for item's place and adds it
+// there, joining an existing tied bucket on an Equal answer.
+func (in *Inserter) Insert(item string) {
+	lo, hi := 0, len(in.ranking)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch in.cmp(in.ranking[mid][0], item) {
+		case Equal:
+			in.ranking[mid] = append(in.ranking[mid], item)
+			return
+		case Less:
+			lo = mid + 1
+		default: // Greater
+			hi = mid
+		}
+	}
+	in.ranking = append(in.ranking, nil)
+	copy(in.ranking[lo+1:], in.ranking[lo:])
+	in.ranking[lo] = []string{item}
+}
+
+// Ranking returns the current best-to-worst ranking.
+func (in *Inserter) Ranking() Ranking {
+	return in.ranking
+}
+
+// streamAdditions keeps inserting newly-seen items into ranking for as long
+// as any enabled source is still live, printing the updated ranking after
+// each insertion. Items already present in ranking are ignored.
+//
+// Every source needs a way to signal done, or streamAdditions could only be
+// stopped by killing the process — which would also mean -o/-cache, applied
+// by the caller once this returns, could never run. -stream has a natural
+// one (stdin EOF); watchFile polls forever by design, so when -watch runs
+// without -stream, stdin is otherwise unused and instead doubles as a "press
+// enter to stop and save" keystroke. Either way, whichever source notices it
+// should stop calls stop() itself; done only needs to close once, so a
+// sync.Once guards against the two racing.
+func streamAdditions(ranking Ranking, cmp Comparator, stream bool, watchPath string) Ranking {
+	if !stream && watchPath == "" {
+		return ranking
+	}
+
+	seen := make(map[string]bool)
+	for _, group := range ranking {
+		for _, it := range group {
+			seen[it] = true
+		}
+	}
+
+	newItems := make(chan string)
+	done := make(chan struct{})
+	var stopOnce sync.Once
+	stop := func() { stopOnce.Do(func() { close(done) }) }
+
+	var producers sync.WaitGroup
+	if stream {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					stop()
+					return
+				}
+				line = strings.TrimSpace(line)
+				if line == "" {
+					continue
+				}
+				select {
+				case newItems <- line:
+				case <-done:
+					return
+				}
+			}
+		}()
+	} else if watchPath != "" {
+		// Nothing else reads stdin in watch-only mode, so it's free to use
+		// as the keystroke that ends the session gracefully (see the
+		// streamAdditions doc comment).
+		fmt.Printf("\nWatching %s for new items. Press enter to stop and save.\n", watchPath)
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			reader.ReadString('\n')
+			stop()
+		}()
+	}
+
+	var watcherDone sync.WaitGroup
+	if watchPath != "" {
+		watcherDone.Add(1)
+		go func() {
+			defer watcherDone.Done()
+			watchFile(watchPath, newItems, done)
+		}()
+	}
+
+	go func() {
+		producers.Wait()
+		stop()
+		watcherDone.Wait()
+		close(newItems)
+	}()
+
+	inserter := NewInserter(ranking, cmp)
+	for item := range newItems {
+		if seen[item] {
+			continue
+		}
+		seen[item] = true
+		fmt.Printf("\nNew item appended: %s\n", item)
+		inserter.Insert(item)
+		fmt.Println("Updated ranking (best -> worst):")
+		printRanking(inserter.Ranking())
+	}
+
+	return inserter.Ranking()
+}
+
+// watchFile polls path for appended content every 500ms, emitting each new
+// non-empty line on out, until done is closed.
+func watchFile(path string, out chan<- string, done <-chan struct{}) {
+	var lastSize int64
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			if int64(len(data)) <= lastSize {
+				continue
+			}
+			added := data[lastSize:]
+			lastSize = int64(len(data))
+			for _, line := range strings.Split(string(added), "\n") {
+				line = strings.TrimSpace(line)
+				if line != "" {
+					out <- line
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStreamAdditionsStopsOnStdinEOF guards against the watcher/stdin
+// shutdown cycle: watchFile only returns once done closes, and done must
+// close without waiting on watchFile to return first, or streamAdditions
+// would hang forever once stdin is exhausted.
+func TestStreamAdditionsStopsOnStdinEOF(t *testing.T) {
+	watchPath := t.TempDir() + "/watched.txt"
+	if err := os.WriteFile(watchPath, nil, 0644); err != nil {
+		t.Fatalf("failed to create watch file: %v", err)
+	}
+
+	oldReader := reader
+	reader = bufio.NewReader(strings.NewReader("stdin-item\n"))
+	defer func() { reader = oldReader }()
+
+	cmp := Comparator(func(a, b string) Ordering { return Less })
+	ranking := singletons([]string{"existing"})
+
+	resultCh := make(chan Ranking, 1)
+	go func() {
+		resultCh <- streamAdditions(ranking, cmp, true, watchPath)
+	}()
+
+	select {
+	case result := <-resultCh:
+		names := map[string]bool{}
+		for _, group := range result {
+			for _, it := range group {
+				names[it] = true
+			}
+		}
+		if !names["stdin-item"] {
+			t.Fatalf("expected stdin-item to be inserted, got %v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("streamAdditions did not return after stdin EOF; watcher/stdin shutdown deadlocked")
+	}
+}
+
+// TestStreamAdditionsWatchOnlyStopsOnKeystroke guards against -watch without
+// -stream running forever: with no stdin reader competing for it, a single
+// line on stdin must be enough to end the session and return.
+func TestStreamAdditionsWatchOnlyStopsOnKeystroke(t *testing.T) {
+	watchPath := t.TempDir() + "/watched.txt"
+	if err := os.WriteFile(watchPath, nil, 0644); err != nil {
+		t.Fatalf("failed to create watch file: %v", err)
+	}
+
+	oldReader := reader
+	reader = bufio.NewReader(strings.NewReader("\n"))
+	defer func() { reader = oldReader }()
+
+	cmp := Comparator(func(a, b string) Ordering { return Less })
+	ranking := singletons([]string{"existing"})
+
+	resultCh := make(chan Ranking, 1)
+	go func() {
+		resultCh <- streamAdditions(ranking, cmp, false, watchPath)
+	}()
+
+	select {
+	case <-resultCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("streamAdditions did not return after the stop keystroke; watch-only mode is stuck until killed")
+	}
+}
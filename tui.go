@@ -3,84 +3,43 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// compareRequest is sent by the sorter to the UI; the UI must send the boolean
-// response on Resp (true => prefer A, false => prefer B).
-type compareRequest struct {
-	A, B string
-	Resp chan bool
-}
-
-// RunTUI launches a Bubbletea TUI that displays two items side-by-side as "cards"
-// and lets the user choose using the left/right arrow keys (or 'h'/'l').
-// It drives a goroutine that runs the merge-sort and supplies comparison requests
-// to the UI via compCh. When sorting is done the final ranking is printed.
-func RunTUI(items []string) []string {
-	compCh := make(chan compareRequest)
-	sortDoneCh := make(chan struct{})
-
-	// channel where sorted result will be delivered
-	doneCh := make(chan []string)
-
-	// start sorter in background; it will send compareRequest into compCh
-	go func() {
-		sorted := mergeSortWithCmp(items, func(a, b string) bool {
-			req := compareRequest{A: a, B: b, Resp: make(chan bool)}
-			compCh <- req
-			return <-req.Resp
-		})
-		// deliver sorted result first
-		doneCh <- sorted
-		// signal done to pump so it can quit the UI automatically
-		close(sortDoneCh)
-		// then close compCh to unblock any readers
-		close(compCh)
-	}()
-
-	// Initialize and run Bubbletea program
-	p := tea.NewProgram(initialModel(compCh))
-
-	// ensure UI quits when sorter finishes and capture sorted result
-	sortedReady := make(chan []string, 1)
-	go func() {
-		s := <-doneCh
-		// deliver result to be returned after program exits
-		sortedReady <- s
-		// request UI to quit (in case pump misses it)
-		p.Send(quitMsg{})
-	}()
-
-	// pump compare requests into the program using Program.Send.
-	// We also listen for the sorter-done signal and send quit when sorting finishes.
-	go func() {
-		for {
-			select {
-			case req, ok := <-compCh:
-				if !ok {
-					// channel closed; ensure quit is sent and exit pump
-					p.Send(quitMsg{})
-					return
-				}
-				p.Send(cmpRequestMsg(req))
-			case <-sortDoneCh:
-				// sorter finished; request UI to quit
-				p.Send(quitMsg{})
-				return
-			}
-		}
-	}()
-
-	if _, err := p.Run(); err != nil {
+// RunTUI launches a Bubbletea TUI that displays two items side-by-side as
+// "cards" and lets the user choose using the left/right arrow keys (or
+// 'h'/'l'), with 3 (or =) for a tie. If cache is non-nil, comparisons it can
+// already answer are applied silently without prompting.
+//
+// algo selects the driving algorithm: "merge" runs on a SortEngine, which
+// also lets the user undo ('u'), redo (ctrl-r), or stop early ('s') and take
+// the best-effort ranking so far; "ford-johnson" asks the fewest possible
+// questions but, since it isn't built on an externalized, replayable log,
+// doesn't support any of that — including streaming, so stream/watchPath are
+// applied the same way as non-TUI runs, once this returns.
+//
+// For "merge", stream and watchPath wire straight into the session: once the
+// initial ranking is done, watchPath (if set) is polled live via a tea.Cmd,
+// and the 'a' key lets the user type in a new item by hand, both inserted
+// with the same binary search streamAdditions uses (see model.enterStreaming
+// and Inserter). stream (newline-delimited items piped over stdin) isn't
+// wired in, since Bubbletea already owns stdin to read keystrokes; it's
+// applied the same way as non-TUI runs, after this returns.
+func RunTUI(items []string, cache *PreferenceCache, algo string, stream bool, watchPath string) Ranking {
+	if algo == "ford-johnson" {
+		return runFordJohnsonTUI(items, cache)
+	}
+	m := newModel(items, cache, stream, watchPath)
+	p := tea.NewProgram(m)
+	final, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to run TUI: %v\n", err)
 		os.Exit(1)
 	}
-
-	// Wait for sorter result and return it
-	sorted := <-sortedReady
-	return sorted
+	return final.(model).result
 }
 
 // ----------------------
@@ -88,122 +47,559 @@ func RunTUI(items []string) []string {
 // ----------------------
 
 type model struct {
-	// channel receiving compareRequest from sorter
-	compCh <-chan compareRequest
+	engine *SortEngine
+	cache  *PreferenceCache
 
-	// the currently displayed pair (when non-empty)
-	a, b string
-
-	// the current active request to answer; nil when idle
-	req chan bool
+	// the currently displayed pair, valid when awaiting != false
+	a, b     string
+	awaiting bool
 
-	// a small status message
 	status string
+
+	// result is populated once sorting finishes or the user stops early.
+	result Ranking
+	done   bool
+
+	// streaming is entered once the initial ranking is done, if
+	// streamEnabled: watchPath (if set) is polled live and the 'a' key lets
+	// the user type in a new item, both inserted via inserter (see
+	// enterStreaming, startInsert).
+	streamEnabled bool
+	watchPath     string
+	watchSize     int64
+	streaming     bool
+	seen          map[string]bool
+	inserter      *Inserter
+	pending       []string // items waiting for inserting to finish
+	inserting     bool
+	insertEvents  <-chan tea.Msg
+	insertResp    chan Ordering // non-nil while awaiting an answer for the in-flight Insert
+	adding        bool          // true while the user is typing a new item's name after 'a'
+	addBuf        string
+}
+
+const helpLine = "Use ← / → (or h / l) to choose, 3 (or =) for a tie. u undo, ctrl-r redo, s stop early, q quit."
+const insertHelpLine = "Use ← / → (or h / l) to choose, 3 (or =) for a tie."
+
+func newModel(items []string, cache *PreferenceCache, stream bool, watchPath string) model {
+	m := model{
+		engine:        NewSortEngine(items),
+		cache:         cache,
+		status:        helpLine,
+		streamEnabled: stream || watchPath != "",
+		watchPath:     watchPath,
+	}
+	m.advance()
+	return m
+}
+
+// streamHelpLine describes how to add an item during the streaming phase.
+func streamHelpLine(watchPath string) string {
+	if watchPath != "" {
+		return fmt.Sprintf("Ranking complete. Watching %s for new items; press 'a' to add one by hand, q to finish.", watchPath)
+	}
+	return "Ranking complete. Press 'a' to add a new item, q to finish."
 }
 
-func initialModel(compCh <-chan compareRequest) model {
-	return model{compCh: compCh, status: "Waiting for first comparison..."}
+// advance fast-forwards through any comparisons the cache can already answer
+// and stops at the next one that needs a human, or finalizes the result if
+// sorting is complete.
+func (m *model) advance() {
+	for {
+		if m.engine.Done() {
+			m.result = m.engine.Result()
+			m.done = true
+			m.awaiting = false
+			return
+		}
+		a, b, _ := m.engine.Next()
+		if m.cache != nil {
+			if ord, known := m.cache.Infer(a, b); known {
+				m.engine.Answer(ord)
+				continue
+			}
+		}
+		m.a, m.b = a, b
+		m.awaiting = true
+		return
+	}
 }
 
 // Messages
-type cmpRequestMsg compareRequest
 type quitMsg struct{}
-type tickMsg struct{}
 
 func (m model) Init() tea.Cmd {
-	// No-op; RunTUI pumps compare requests into the program via p.Send.
 	return nil
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.streaming {
+		return m.updateStreaming(msg)
+	}
+	if m.done {
+		if m.streamEnabled {
+			return m.enterStreaming()
+		}
+		return m, tea.Quit
+	}
 	switch msg := msg.(type) {
-	case cmpRequestMsg:
-		req := compareRequest(msg)
-		m.a = req.A
-		m.b = req.B
-		m.req = req.Resp
-		m.status = "Use ← / → (or h / l) to choose. Enter q to quit."
-		// after handling this request, schedule another read for the next request
-		return m, m.Init()
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "left", "h":
-			if m.req != nil {
-				m.req <- true
-				m.req = nil
-				m.status = "Sent choice: left"
+			if m.awaiting {
+				m.recordAnswer(Less)
 			}
-			return m, nil
+			return m.checkStreamTransition()
 		case "right", "l":
-			if m.req != nil {
-				m.req <- false
-				m.req = nil
-				m.status = "Sent choice: right"
+			if m.awaiting {
+				m.recordAnswer(Greater)
 			}
-			return m, nil
+			return m.checkStreamTransition()
+		case "3", "=":
+			if m.awaiting {
+				m.recordAnswer(Equal)
+			}
+			return m.checkStreamTransition()
+		case "u":
+			if m.engine.Undo() {
+				m.status = "Undid last answer. " + helpLine
+				m.advance()
+			}
+			return m.checkStreamTransition()
+		case "ctrl+r":
+			if m.engine.Redo() {
+				m.status = "Redid last undone answer. " + helpLine
+				m.advance()
+			}
+			return m.checkStreamTransition()
+		case "s":
+			m.result = m.engine.PartialResult()
+			m.done = true
+			if !m.streamEnabled {
+				return m, tea.Quit
+			}
+			return m.enterStreaming()
 		case "q", "ctrl+c":
-			// quit the program immediately
-			return m, tea.Quit
+			m.result = m.engine.PartialResult()
+			m.done = true
+			if !m.streamEnabled {
+				return m, tea.Quit
+			}
+			return m.enterStreaming()
 		}
 	case quitMsg:
 		return m, tea.Quit
 	case tea.WindowSizeMsg:
 		// ignore for now, could be used to layout cards
-	case nil:
-		// ignore
 	}
 	return m, nil
 }
 
+// checkStreamTransition moves into the streaming phase once the initial
+// ranking has just finished and streaming was requested.
+func (m model) checkStreamTransition() (tea.Model, tea.Cmd) {
+	if m.done && m.streamEnabled && !m.streaming {
+		return m.enterStreaming()
+	}
+	return m, nil
+}
+
+// recordAnswer applies a human answer to the currently displayed pair,
+// records it in the cache (if any), and advances to the next pending pair.
+func (m *model) recordAnswer(ord Ordering) {
+	m.engine.Answer(ord)
+	if m.cache != nil {
+		switch ord {
+		case Less:
+			m.cache.Record(m.a, m.b)
+		case Greater:
+			m.cache.Record(m.b, m.a)
+		case Equal:
+			m.cache.RecordTie(m.a, m.b)
+		}
+	}
+	m.status = helpLine
+	m.advance()
+}
+
 func (m model) View() string {
 	out := "\nInteractive Tournament — choose which item you prefer\n\n"
-	if m.a == "" && m.b == "" {
+	if m.streaming {
+		if m.adding {
+			return out + fmt.Sprintf("Add item> %s\n", m.addBuf)
+		}
+		if m.awaiting {
+			out += fmt.Sprintf("  ← %-30s    %-30s →\n\n", m.a, m.b)
+		}
+		out += fmt.Sprintf("  %s\n\n", m.status)
+		return out
+	}
+	if !m.awaiting {
 		out += fmt.Sprintf("%s\n", m.status)
 		return out
 	}
-	// Simple side-by-side "cards" using fixed-width formatting
 	out += fmt.Sprintf("  ← %-30s    %-30s →\n\n", m.a, m.b)
 	out += fmt.Sprintf("  %s\n\n", m.status)
 	return out
 }
 
 // ----------------------
-// sorter with comparator
+// streaming phase: -watch and the 'a' key, live in the TUI
 // ----------------------
 
-// mergeSortWithCmp is a standard merge-sort that uses cmp(a,b) to decide
-// whether a should come before b (cmp returns true if a preferred over b).
-func mergeSortWithCmp(items []string, cmp func(a, b string) bool) []string {
-	if len(items) <= 1 {
-		out := make([]string, len(items))
-		copy(out, items)
-		return out
+// watchTickMsg reports what watchPollCmd found on its latest poll.
+type watchTickMsg struct {
+	newSize int64
+	lines   []string
+}
+
+// watchPollCmd polls watchPath once, 500ms out, for content appended after
+// lastSize — the tea.Cmd equivalent of watchFile's ticker loop.
+func watchPollCmd(watchPath string, lastSize int64) tea.Cmd {
+	return tea.Tick(500*time.Millisecond, func(time.Time) tea.Msg {
+		data, err := os.ReadFile(watchPath)
+		if err != nil || int64(len(data)) <= lastSize {
+			return watchTickMsg{newSize: lastSize}
+		}
+		var lines []string
+		for _, line := range strings.Split(string(data[lastSize:]), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return watchTickMsg{newSize: int64(len(data)), lines: lines}
+	})
+}
+
+// insertCompareMsg is sent by an in-flight Insert call when it needs a human
+// answer the cache can't already supply.
+type insertCompareMsg struct {
+	a, b string
+	resp chan Ordering
+}
+
+// insertDoneMsg reports that item has finished being placed into ranking.
+type insertDoneMsg struct {
+	item    string
+	ranking Ranking
+}
+
+// waitForInsertEvent turns the next message off of an in-flight insertion's
+// event channel into a Bubbletea message.
+func waitForInsertEvent(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg { return <-ch }
+}
+
+// startInsert runs inserter.Insert(item) in the background, since its binary
+// search may need several human answers in a row and each one has to round
+// trip through an Update call before the next comparison is known. Answers
+// the cache already has are applied without a round trip, same as advance.
+func startInsert(inserter *Inserter, cache *PreferenceCache, item string) <-chan tea.Msg {
+	events := make(chan tea.Msg)
+	inserter.cmp = func(a, b string) Ordering {
+		if cache != nil {
+			if ord, known := cache.Infer(a, b); known {
+				return ord
+			}
+		}
+		resp := make(chan Ordering)
+		events <- insertCompareMsg{a: a, b: b, resp: resp}
+		ord := <-resp
+		if cache != nil {
+			switch ord {
+			case Less:
+				cache.Record(a, b)
+			case Greater:
+				cache.Record(b, a)
+			case Equal:
+				cache.RecordTie(a, b)
+			}
+		}
+		return ord
 	}
-	mid := len(items) / 2
-	left := mergeSortWithCmp(items[:mid], cmp)
-	right := mergeSortWithCmp(items[mid:], cmp)
-	return mergeWithCmp(left, right, cmp)
+	go func() {
+		inserter.Insert(item)
+		events <- insertDoneMsg{item: item, ranking: inserter.Ranking()}
+	}()
+	return events
 }
 
-func mergeWithCmp(left, right []string, cmp func(a, b string) bool) []string {
-	i, j := 0, 0
-	out := make([]string, 0, len(left)+len(right))
-	for i < len(left) && j < len(right) {
-		if cmp(left[i], right[j]) {
-			out = append(out, left[i])
-			i++
-		} else {
-			out = append(out, right[j])
-			j++
+// enterStreaming switches the model from ranking to inserting new items
+// (from -watch and/or the 'a' key) into the finished result without leaving
+// the TUI, starting the watch poll if configured.
+func (m model) enterStreaming() (tea.Model, tea.Cmd) {
+	m.streaming = true
+	m.seen = make(map[string]bool)
+	for _, group := range m.result {
+		for _, it := range group {
+			m.seen[it] = true
 		}
 	}
-	for i < len(left) {
-		out = append(out, left[i])
-		i++
+	m.inserter = NewInserter(m.result, nil)
+	m.status = streamHelpLine(m.watchPath)
+	if m.watchPath != "" {
+		return m, watchPollCmd(m.watchPath, 0)
+	}
+	return m, nil
+}
+
+// enqueue adds item to the pending insertion queue, skipping it if it's
+// already in the ranking or already queued.
+func (m *model) enqueue(item string) {
+	if m.seen[item] {
+		return
 	}
-	for j < len(right) {
-		out = append(out, right[j])
-		j++
+	m.seen[item] = true
+	m.pending = append(m.pending, item)
+}
+
+// maybeStartNextInsert begins inserting the next queued item if nothing is
+// currently being inserted, returning the Cmd that pumps its events (or nil
+// if nothing is pending or an insertion is already in flight).
+func (m *model) maybeStartNextInsert() tea.Cmd {
+	if m.inserting || len(m.pending) == 0 {
+		return nil
 	}
+	item := m.pending[0]
+	m.pending = m.pending[1:]
+	m.inserting = true
+	m.insertEvents = startInsert(m.inserter, m.cache, item)
+	return waitForInsertEvent(m.insertEvents)
+}
+
+func (m model) updateStreaming(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case watchTickMsg:
+		m.watchSize = msg.newSize
+		for _, line := range msg.lines {
+			m.enqueue(line)
+		}
+		cmd := m.maybeStartNextInsert()
+		return m, tea.Batch(watchPollCmd(m.watchPath, m.watchSize), cmd)
+	case insertCompareMsg:
+		m.a, m.b = msg.a, msg.b
+		m.awaiting = true
+		m.insertResp = msg.resp
+		m.status = insertHelpLine
+		return m, waitForInsertEvent(m.insertEvents)
+	case insertDoneMsg:
+		m.inserting = false
+		m.awaiting = false
+		fmt.Printf("\nInserted %q. Updated ranking:\n", msg.item)
+		printRanking(msg.ranking)
+		cmd := m.maybeStartNextInsert()
+		if cmd == nil {
+			m.status = streamHelpLine(m.watchPath)
+		}
+		return m, cmd
+	case tea.KeyMsg:
+		if m.adding {
+			return m.updateAddBuf(msg)
+		}
+		switch msg.String() {
+		case "left", "h":
+			if m.insertResp != nil {
+				m.insertResp <- Less
+				m.insertResp = nil
+				return m, waitForInsertEvent(m.insertEvents)
+			}
+		case "right", "l":
+			if m.insertResp != nil {
+				m.insertResp <- Greater
+				m.insertResp = nil
+				return m, waitForInsertEvent(m.insertEvents)
+			}
+		case "3", "=":
+			if m.insertResp != nil {
+				m.insertResp <- Equal
+				m.insertResp = nil
+				return m, waitForInsertEvent(m.insertEvents)
+			}
+		case "a":
+			if m.insertResp == nil {
+				m.adding = true
+				m.addBuf = ""
+				m.status = "Type the new item's name, enter to add, esc to cancel."
+			}
+		case "q", "ctrl+c":
+			if m.inserter != nil {
+				m.result = m.inserter.Ranking()
+			}
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// updateAddBuf handles keystrokes while the user is typing a new item's name
+// after pressing 'a' — the in-TUI stand-in for -stream's stdin pipe, which
+// can't share stdin with Bubbletea's own keystroke reading.
+func (m model) updateAddBuf(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		item := strings.TrimSpace(m.addBuf)
+		m.adding = false
+		if item != "" {
+			m.enqueue(item)
+		}
+		cmd := m.maybeStartNextInsert()
+		if cmd == nil {
+			m.status = streamHelpLine(m.watchPath)
+		}
+		return m, cmd
+	case tea.KeyEsc:
+		m.adding = false
+		m.status = streamHelpLine(m.watchPath)
+		return m, nil
+	case tea.KeyBackspace:
+		if len(m.addBuf) > 0 {
+			m.addBuf = m.addBuf[:len(m.addBuf)-1]
+		}
+		return m, nil
+	case tea.KeyRunes:
+		m.addBuf += string(msg.Runes)
+		return m, nil
+	}
+	return m, nil
+}
+
+// ----------------------
+// batch comparator driver (non-interactive use)
+// ----------------------
+
+// mergeSortWithCmp sorts items using cmp to decide how each pair should be
+// ranked relative to each other. It drives a SortEngine to completion,
+// feeding every pending comparison straight to cmp.
+func mergeSortWithCmp(items []string, cmp Comparator) Ranking {
+	e := NewSortEngine(items)
+	for {
+		a, b, ok := e.Next()
+		if !ok {
+			return e.Result()
+		}
+		e.Answer(cmp(a, b))
+	}
+}
+
+// ----------------------
+// Ford-Johnson TUI driver
+// ----------------------
+
+// fjCompareRequest is sent by the sorter to the UI; the UI must answer on
+// Resp.
+type fjCompareRequest struct {
+	A, B string
+	Resp chan Ordering
+}
+
+type fjCmpRequestMsg fjCompareRequest
+type fjResultMsg []string
+
+const fjHelpLine = "Use ← / → (or h / l) to choose, 3 (or =) for a tie. q to quit."
+
+// runFordJohnsonTUI drives fordJohnsonSort from a background goroutine,
+// pumping its comparison requests into a minimal Bubbletea model. Quitting
+// before the sort finishes returns through p.Run() like any other exit (so
+// main's cache.Save still runs for whatever answers were given); the sorter
+// goroutine is simply left blocked on the unanswered comparison and is
+// reclaimed when the process exits.
+func runFordJohnsonTUI(items []string, cache *PreferenceCache) Ranking {
+	compCh := make(chan fjCompareRequest)
+	doneCh := make(chan []string, 1)
+
+	go func() {
+		cmp := Comparator(func(a, b string) Ordering {
+			req := fjCompareRequest{A: a, B: b, Resp: make(chan Ordering)}
+			compCh <- req
+			return <-req.Resp
+		})
+		if cache != nil {
+			cmp = cache.oracle(cmp)
+		}
+		doneCh <- fordJohnsonSort(items, cmp)
+	}()
+
+	p := tea.NewProgram(fjModel{status: fjHelpLine})
+
+	go func() {
+		for req := range compCh {
+			p.Send(fjCmpRequestMsg(req))
+		}
+	}()
+	go func() {
+		p.Send(fjResultMsg(<-doneCh))
+	}()
+
+	final, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to run TUI: %v\n", err)
+		os.Exit(1)
+	}
+
+	m := final.(fjModel)
+	if m.sorted == nil {
+		// Quit before the sort finished; Ford-Johnson isn't resumable from a
+		// partial answer log the way the merge engine is, so there's no
+		// best-effort ranking to offer beyond the original order.
+		return singletons(items)
+	}
+	return singletons(m.sorted)
+}
+
+type fjModel struct {
+	a, b   string
+	req    chan Ordering
+	status string
+	sorted []string
+}
+
+func (m fjModel) Init() tea.Cmd { return nil }
+
+func (m fjModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case fjCmpRequestMsg:
+		req := fjCompareRequest(msg)
+		m.a, m.b, m.req = req.A, req.B, req.Resp
+		m.status = fjHelpLine
+		return m, nil
+	case fjResultMsg:
+		m.sorted = []string(msg)
+		return m, tea.Quit
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "left", "h":
+			if m.req != nil {
+				m.req <- Less
+				m.req = nil
+			}
+			return m, nil
+		case "right", "l":
+			if m.req != nil {
+				m.req <- Greater
+				m.req = nil
+			}
+			return m, nil
+		case "3", "=":
+			if m.req != nil {
+				m.req <- Equal
+				m.req = nil
+			}
+			return m, nil
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m fjModel) View() string {
+	out := "\nInteractive Tournament — choose which item you prefer\n\n"
+	if m.req == nil {
+		out += fmt.Sprintf("%s\n", m.status)
+		return out
+	}
+	out += fmt.Sprintf("  ← %-30s    %-30s →\n\n", m.a, m.b)
+	out += fmt.Sprintf("  %s\n\n", m.status)
 	return out
 }